@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned by StatFile/StatFileAbsolute when the requested key
+// doesn't exist, so callers can distinguish "missing" from other I/O errors.
+var ErrNotFound = errors.New("object not found")
+
+// RemoteStorage is implemented by every backup storage backend (Local, S3,
+// GCS, AzureBlob, SFTP, ...).
+type RemoteStorage interface {
+	Kind() string
+	Connect(ctx context.Context) error
+	Close(ctx context.Context) error
+	StatFile(ctx context.Context, key string) (RemoteFile, error)
+	StatFileAbsolute(ctx context.Context, key string) (RemoteFile, error)
+	DeleteFile(ctx context.Context, key string) error
+	DeleteFileFromObjectDiskBackup(ctx context.Context, key string) error
+	Walk(ctx context.Context, remotePath string, recursive bool, process func(context.Context, RemoteFile) error) error
+	WalkAbsolute(ctx context.Context, prefix string, recursive bool, process func(context.Context, RemoteFile) error) error
+	GetFileReader(ctx context.Context, key string) (io.ReadCloser, error)
+	GetFileReaderAbsolute(ctx context.Context, key string) (io.ReadCloser, error)
+	GetFileReaderWithLocalPath(ctx context.Context, key, localPath string, remoteSize int64) (io.ReadCloser, error)
+	PutFile(ctx context.Context, key string, r io.ReadCloser, localSize int64) error
+	PutFileAbsolute(ctx context.Context, key string, r io.ReadCloser, localSize int64) error
+	CopyObject(ctx context.Context, srcSize int64, srcBucket, srcKey, dstKey string) (int64, error)
+}
+
+// RemoteFile describes a single object returned by StatFile/Walk.
+//
+// Hash returns the digest a backend has on record for t, mirroring rclone's
+// fs.ObjectInfo.Hash. Backends that don't track a given algorithm (or any
+// hash at all) must return ("", false) rather than erroring, so Walk
+// consumers can always call it and fall back to a full content comparison
+// when it comes back empty. S3/GCS/AzureBlob backends in this repo return
+// HashNone/false unconditionally until they grow their own hash tracking.
+type RemoteFile interface {
+	Size() int64
+	LastModified() time.Time
+	Name() string
+	Hash(t HashType) (string, bool)
+}
+
+// BatchDeleter is implemented by backends that can delete many keys more
+// efficiently than one DeleteFile call per key.
+type BatchDeleter interface {
+	DeleteKeysBatch(ctx context.Context, keys []string) error
+	DeleteKeysFromObjectDiskBackupBatch(ctx context.Context, keys []string) error
+}
+
+// KeyError pairs a single failed key with the error that caused the failure.
+type KeyError struct {
+	Key string
+	Err error
+}
+
+// BatchDeleteError aggregates per-key failures from a batch delete so callers
+// can report both the overall outcome and which keys need a retry.
+type BatchDeleteError struct {
+	Message  string
+	Failures []KeyError
+}
+
+func (e *BatchDeleteError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Message, e.Failures)
+}
@@ -7,16 +7,54 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Altinity/clickhouse-backup/v2/pkg/config"
 	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
 )
 
 // Local implements RemoteStorage and BatchDeleter for local filesystem paths
 type Local struct {
-	Config *config.LocalConfig
+	Config      *config.LocalConfig
+	dedupIdx    *dedupIndex
+	dedupMu     sync.Once
+	throttle    *ioAccounting
+	throttleMu  sync.Once
+	uploadLim   *rate.Limiter
+	downloadLim *rate.Limiter
+	iopsLim     *rate.Limiter
+}
+
+// limiters lazily builds the upload/download/IOPS token buckets from
+// LocalConfig, shared across every concurrent worker so the aggregate
+// throughput (not each goroutine individually) stays under the configured cap.
+func (l *Local) limiters() (*rate.Limiter, *rate.Limiter, *rate.Limiter, *ioAccounting) {
+	l.throttleMu.Do(func() {
+		l.throttle = &ioAccounting{}
+		l.uploadLim = rateLimiter(l.Config.MaxUploadBytesPerSec)
+		l.downloadLim = rateLimiter(l.Config.MaxDownloadBytesPerSec)
+		l.iopsLim = rateLimiter(l.Config.MaxIOPS)
+	})
+	return l.uploadLim, l.downloadLim, l.iopsLim, l.throttle
+}
+
+// dedup lazily loads the dedup index rooted at ObjectDiskPath (falling back to
+// Path), so Connect doesn't pay the cost for configs that never enable dedup.
+func (l *Local) dedup() *dedupIndex {
+	l.dedupMu.Do(func() {
+		basePath := l.Config.ObjectDiskPath
+		if basePath == "" {
+			basePath = l.Config.Path
+		}
+		l.dedupIdx = loadDedupIndex(basePath)
+	})
+	return l.dedupIdx
 }
 
 func (l *Local) Debug(msg string, v ...interface{}) {
@@ -57,6 +95,9 @@ func (l *Local) Connect(ctx context.Context) error {
 }
 
 func (l *Local) Close(ctx context.Context) error {
+	if l.dedupIdx != nil && l.dedupIdx.log != nil {
+		return l.dedupIdx.log.Close()
+	}
 	return nil
 }
 
@@ -81,6 +122,7 @@ func (l *Local) StatFileAbsolute(ctx context.Context, key string) (RemoteFile, e
 		size:         stat.Size(),
 		lastModified: stat.ModTime(),
 		name:         filepath.Base(key),
+		absPath:      key,
 	}, nil
 }
 
@@ -126,10 +168,14 @@ func (l *Local) WalkAbsolute(ctx context.Context, prefix string, recursive bool,
 			if relName == "." {
 				return nil
 			}
+			if isInternalArtifact(info.Name()) {
+				return nil
+			}
 			return process(ctx, &localFile{
 				size:         info.Size(),
 				lastModified: info.ModTime(),
 				name:         relName,
+				absPath:      fPath,
 			})
 		})
 	}
@@ -143,6 +189,9 @@ func (l *Local) WalkAbsolute(ctx context.Context, prefix string, recursive bool,
 		return err
 	}
 	for _, entry := range entries {
+		if isInternalArtifact(entry.Name()) {
+			continue
+		}
 		info, infoErr := entry.Info()
 		if infoErr != nil {
 			return infoErr
@@ -151,6 +200,7 @@ func (l *Local) WalkAbsolute(ctx context.Context, prefix string, recursive bool,
 			size:         info.Size(),
 			lastModified: info.ModTime(),
 			name:         entry.Name(),
+			absPath:      filepath.Join(prefix, entry.Name()),
 		}); err != nil {
 			return err
 		}
@@ -167,7 +217,38 @@ func (l *Local) GetFileReader(ctx context.Context, key string) (io.ReadCloser, e
 }
 
 func (l *Local) GetFileReaderAbsolute(ctx context.Context, key string) (io.ReadCloser, error) {
-	return os.Open(key)
+	f, err := os.Open(key)
+	if err != nil {
+		return nil, err
+	}
+	var result io.ReadCloser = f
+
+	_, downloadLim, iopsLim, accounting := l.limiters()
+	if iopsLim != nil {
+		if err := iopsLim.WaitN(ctx, 1); err != nil {
+			if closeErr := f.Close(); closeErr != nil {
+				log.Warn().Msgf("can't close %s err=%v", key, closeErr)
+			}
+			return nil, err
+		}
+	}
+	if downloadLim != nil {
+		result = &throttledReadCloser{rc: result, r: throttledReader{r: result, limiter: downloadLim, ctx: ctx, accounting: accounting}}
+	}
+
+	if !l.Config.VerifyHashOnRead {
+		return result, nil
+	}
+	hashType, digest, ok := readHashSidecar(key)
+	if !ok {
+		return result, nil
+	}
+	hasher, err := newHasher(hashType)
+	if err != nil {
+		l.Debug("[LOCAL_DEBUG] GetFileReaderAbsolute %s: %v, skipping verification", key, err)
+		return result, nil
+	}
+	return &hashVerifyReadCloser{ReadCloser: result, hasher: hasher, expected: digest, name: key}, nil
 }
 
 func (l *Local) GetFileReaderWithLocalPath(ctx context.Context, key, localPath string, remoteSize int64) (io.ReadCloser, error) {
@@ -182,30 +263,110 @@ func (l *Local) PutFile(ctx context.Context, key string, r io.ReadCloser, localS
 	return l.PutFileAbsolute(ctx, absPath, r, localSize)
 }
 
+// durability level for PutFileAbsolute/CopyObject, from LocalConfig.Durability.
+const (
+	durabilityNone = iota
+	durabilityData
+	durabilityFull
+)
+
+// durability maps LocalConfig.Durability ("none"/"data"/"full") to a level,
+// defaulting to "data" (fsync the file but not its parent directory).
+func (l *Local) durability() int {
+	switch strings.ToLower(l.Config.Durability) {
+	case "none":
+		return durabilityNone
+	case "full":
+		return durabilityFull
+	default:
+		return durabilityData
+	}
+}
+
+// PutFileAbsolute writes to a temp file in the same directory, fsyncs it,
+// renames it over the final name, then (at Durability "full") fsyncs the
+// parent directory too. This avoids leaving a zero-length or truncated file
+// at `key` if the process crashes mid-write, per Durability "none"/"data"/"full".
 func (l *Local) PutFileAbsolute(ctx context.Context, key string, r io.ReadCloser, localSize int64) error {
 	dir := filepath.Dir(key)
 	if err := os.MkdirAll(dir, 0750); err != nil {
 		return fmt.Errorf("can't create directory %s: %v", dir, err)
 	}
-	dst, err := os.Create(key)
+	dst, err := os.CreateTemp(dir, filepath.Base(key)+".tmp-*")
 	if err != nil {
 		return err
 	}
-	defer func() {
-		if closeErr := dst.Close(); closeErr != nil {
-			log.Warn().Msgf("can't close %s err=%v", key, closeErr)
+	tmpPath := dst.Name()
+	cleanup := func() {
+		if removeErr := os.Remove(tmpPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			log.Warn().Msgf("can't remove partial file %s err=%v", tmpPath, removeErr)
 		}
-	}()
-	if _, err = io.Copy(dst, r); err != nil {
-		// Clean up partial file on copy failure
-		if removeErr := os.Remove(key); removeErr != nil {
-			log.Warn().Msgf("can't remove partial file %s err=%v", key, removeErr)
+	}
+
+	uploadLim, _, iopsLim, accounting := l.limiters()
+	if iopsLim != nil {
+		if err := iopsLim.WaitN(ctx, 1); err != nil {
+			_ = dst.Close()
+			cleanup()
+			return err
 		}
+	}
+	w := io.Writer(dst)
+	if uploadLim != nil {
+		w = &throttledWriter{w: dst, limiter: uploadLim, ctx: ctx, accounting: accounting}
+	}
+	if _, err = io.Copy(w, r); err != nil {
+		_ = dst.Close()
+		cleanup()
 		return err
 	}
+
+	durability := l.durability()
+	if durability != durabilityNone {
+		if err := dst.Sync(); err != nil {
+			_ = dst.Close()
+			cleanup()
+			return fmt.Errorf("can't fsync %s: %v", tmpPath, err)
+		}
+	}
+	if err := dst.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("can't close %s: %v", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, key); err != nil {
+		cleanup()
+		return fmt.Errorf("can't rename %s to %s: %v", tmpPath, key, err)
+	}
+	if durability == durabilityFull {
+		if err := fsyncDir(dir); err != nil {
+			return fmt.Errorf("can't fsync directory %s: %v", dir, err)
+		}
+	}
+	l.indexHash(key)
 	return nil
 }
 
+// indexHash computes the configured content hash for absPath, records it in
+// the `<path>.hash` sidecar, and registers it in the dedup index so a later
+// CopyObject with identical content can hardlink instead of copying.
+func (l *Local) indexHash(absPath string) {
+	hashType := parseHashType(l.Config.HashType)
+	if hashType == HashNone {
+		return
+	}
+	digest, err := hashFile(absPath, hashType)
+	if err != nil {
+		log.Warn().Msgf("can't hash %s: %v", absPath, err)
+		return
+	}
+	if err := writeHashSidecar(absPath, hashType, digest); err != nil {
+		log.Warn().Msgf("can't write hash sidecar for %s: %v", absPath, err)
+	}
+	if l.Config.Dedup {
+		l.dedup().store(digest, absPath)
+	}
+}
+
 func (l *Local) CopyObject(ctx context.Context, srcSize int64, srcBucket, srcKey, dstKey string) (int64, error) {
 	// Prefix dstKey with ObjectDiskPath, consistent with S3/GCS/AzureBlob
 	dstKey = path.Join(l.Config.ObjectDiskPath, dstKey)
@@ -219,13 +380,48 @@ func (l *Local) CopyObject(ctx context.Context, srcSize int64, srcBucket, srcKey
 		return 0, fmt.Errorf("can't create directory %s: %v", dstDir, err)
 	}
 
-	// Try hardlink first
+	// Consult the dedup index first: if an object with identical content is
+	// already on disk, hardlink onto it instead of touching srcPath at all.
+	// Only trusted on a collision-resistant hash (sha256/blake3) — xxh64 is
+	// fast but a 64-bit digest collision would silently link in wrong bytes.
+	if l.Config.Dedup {
+		if hashType := parseHashType(l.Config.HashType); hashType.collisionResistant() {
+			if sidecarType, digest, ok := readHashSidecar(srcPath); ok && sidecarType == hashType {
+				if existing, found := l.dedup().lookup(digest); found && existing != srcPath {
+					if err := os.Link(existing, dstPath); err == nil {
+						l.Debug("[LOCAL_DEBUG] CopyObject dedup hardlink %s -> %s", existing, dstPath)
+						return srcSize, nil
+					}
+				}
+			}
+		}
+	}
+
+	// Try a copy-on-write clone first, it's instant and space-free on
+	// Btrfs/XFS/ZFS/APFS/ReFS. Falls through silently when unsupported.
+	if l.Config.UseReflink {
+		if written, ok, err := l.tryReflinkCopy(srcPath, dstPath); err != nil {
+			return 0, err
+		} else if ok {
+			l.Debug("[LOCAL_DEBUG] CopyObject reflink %s -> %s", srcPath, dstPath)
+			return written, nil
+		}
+	}
+
+	// Try hardlink next
 	if err := os.Link(srcPath, dstPath); err == nil {
 		l.Debug("[LOCAL_DEBUG] CopyObject hardlink %s -> %s", srcPath, dstPath)
+		if l.durability() == durabilityFull {
+			if err := fsyncDir(dstDir); err != nil {
+				return 0, fmt.Errorf("can't fsync directory %s: %v", dstDir, err)
+			}
+		}
 		return srcSize, nil
 	}
 
-	// Fallback to file copy
+	// Fallback to file copy: write to a temp file in dstDir, fsync, rename
+	// over dstPath, same crash-safety treatment as PutFileAbsolute so a copy
+	// fallback can't leave a truncated object either.
 	l.Debug("[LOCAL_DEBUG] CopyObject copy %s -> %s", srcPath, dstPath)
 	src, err := os.Open(srcPath)
 	if err != nil {
@@ -236,22 +432,83 @@ func (l *Local) CopyObject(ctx context.Context, srcSize int64, srcBucket, srcKey
 			log.Warn().Msgf("can't close %s err=%v", srcPath, closeErr)
 		}
 	}()
-	dst, err := os.Create(dstPath)
+	dst, err := os.CreateTemp(dstDir, filepath.Base(dstPath)+".tmp-*")
 	if err != nil {
 		return 0, err
 	}
-	defer func() {
-		if closeErr := dst.Close(); closeErr != nil {
-			log.Warn().Msgf("can't close %s err=%v", dstPath, closeErr)
+	tmpPath := dst.Name()
+	cleanup := func() {
+		if removeErr := os.Remove(tmpPath); removeErr != nil && !os.IsNotExist(removeErr) {
+			log.Warn().Msgf("can't remove partial file %s err=%v", tmpPath, removeErr)
 		}
-	}()
-	written, err := io.Copy(dst, src)
+	}
+
+	uploadLim, _, iopsLim, accounting := l.limiters()
+	if iopsLim != nil {
+		if err := iopsLim.WaitN(ctx, 1); err != nil {
+			_ = dst.Close()
+			cleanup()
+			return 0, err
+		}
+	}
+	w := io.Writer(dst)
+	if uploadLim != nil {
+		w = &throttledWriter{w: dst, limiter: uploadLim, ctx: ctx, accounting: accounting}
+	}
+	written, err := io.Copy(w, src)
 	if err != nil {
+		_ = dst.Close()
+		cleanup()
 		return 0, err
 	}
+
+	durability := l.durability()
+	if durability != durabilityNone {
+		if err := dst.Sync(); err != nil {
+			_ = dst.Close()
+			cleanup()
+			return 0, fmt.Errorf("can't fsync %s: %v", tmpPath, err)
+		}
+	}
+	if err := dst.Close(); err != nil {
+		cleanup()
+		return 0, fmt.Errorf("can't close %s: %v", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, dstPath); err != nil {
+		cleanup()
+		return 0, fmt.Errorf("can't rename %s to %s: %v", tmpPath, dstPath, err)
+	}
+	if durability == durabilityFull {
+		if err := fsyncDir(dstDir); err != nil {
+			return 0, fmt.Errorf("can't fsync directory %s: %v", dstDir, err)
+		}
+	}
+	l.indexHash(dstPath)
 	return written, nil
 }
 
+// tryReflinkCopy attempts a copy-on-write clone of srcPath onto dstPath via
+// the platform-specific tryReflink (FICLONE on Linux, clonefile on macOS,
+// FSCTL_DUPLICATE_EXTENTS_TO_FILE on Windows/ReFS). All three share the same
+// contract: dstPath must not exist beforehand, and tryReflink itself cleans
+// up any partially-created dstPath on failure. It returns ok=false when the
+// filesystem doesn't support cloning so the caller can fall back to
+// hardlink/copy.
+func (l *Local) tryReflinkCopy(srcPath, dstPath string) (written int64, ok bool, err error) {
+	cloned, cloneErr := tryReflink(srcPath, dstPath)
+	if cloneErr != nil {
+		return 0, false, cloneErr
+	}
+	if !cloned {
+		return 0, false, nil
+	}
+	stat, statErr := os.Stat(dstPath)
+	if statErr != nil {
+		return 0, false, statErr
+	}
+	return stat.Size(), true, nil
+}
+
 // DeleteKeysBatch implements BatchDeleter interface for Local
 func (l *Local) DeleteKeysBatch(ctx context.Context, keys []string) error {
 	if len(keys) == 0 {
@@ -270,37 +527,64 @@ func (l *Local) DeleteKeysFromObjectDiskBackupBatch(ctx context.Context, keys []
 	return l.deleteKeysBatchInternal(ctx, l.Config.ObjectDiskPath, keys)
 }
 
+// concurrency returns the worker pool size for batch operations, defaulting
+// to one worker per CPU when LocalConfig.Concurrency is unset.
+func (l *Local) concurrency() int {
+	if l.Config.Concurrency > 0 {
+		return l.Config.Concurrency
+	}
+	return runtime.NumCPU()
+}
+
 func (l *Local) deleteKeysBatchInternal(ctx context.Context, basePath string, keys []string) error {
-	var failures []KeyError
-	deletedCount := 0
+	var (
+		mu         sync.Mutex
+		failures   []KeyError
+		deletedNum int64
+	)
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(l.concurrency())
 
 	for _, key := range keys {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+		key := key
+		g.Go(func() error {
+			select {
+			case <-gCtx.Done():
+				return gCtx.Err()
+			default:
+			}
 
-		absPath, pathErr := containedPath(basePath, key)
-		if pathErr != nil {
-			failures = append(failures, KeyError{Key: key, Err: pathErr})
-			continue
-		}
-		if err := os.RemoveAll(absPath); err != nil {
-			failures = append(failures, KeyError{Key: key, Err: err})
-			continue
-		}
-		deletedCount++
+			absPath, pathErr := containedPath(basePath, key)
+			if pathErr != nil {
+				mu.Lock()
+				failures = append(failures, KeyError{Key: key, Err: pathErr})
+				mu.Unlock()
+				return nil
+			}
+			if err := os.RemoveAll(absPath); err != nil {
+				mu.Lock()
+				failures = append(failures, KeyError{Key: key, Err: err})
+				mu.Unlock()
+				return nil
+			}
+			atomic.AddInt64(&deletedNum, 1)
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return err
 	}
 
 	if len(failures) > 0 {
 		return &BatchDeleteError{
-			Message:  fmt.Sprintf("LOCAL batch delete: %d keys deleted, %d failed", deletedCount, len(failures)),
+			Message:  fmt.Sprintf("LOCAL batch delete: %d keys deleted, %d failed", deletedNum, len(failures)),
 			Failures: failures,
 		}
 	}
 
-	log.Debug().Msgf("LOCAL batch delete: successfully deleted %d keys", deletedCount)
+	log.Debug().Msgf("LOCAL batch delete: successfully deleted %d keys", deletedNum)
 	return nil
 }
 
@@ -309,6 +593,7 @@ type localFile struct {
 	size         int64
 	lastModified time.Time
 	name         string
+	absPath      string
 }
 
 func (f *localFile) Size() int64 {
@@ -323,6 +608,21 @@ func (f *localFile) Name() string {
 	return f.name
 }
 
+// Hash returns the digest recorded in this file's `.hash` sidecar for the
+// requested HashType, mirroring rclone's fs.ObjectInfo.Hash. It returns
+// ok=false when no sidecar was written or it was written with a different
+// algorithm, letting callers fall back to a full content comparison.
+func (f *localFile) Hash(t HashType) (string, bool) {
+	if f.absPath == "" {
+		return "", false
+	}
+	hashType, digest, ok := readHashSidecar(f.absPath)
+	if !ok || hashType != t {
+		return "", false
+	}
+	return digest, true
+}
+
 // Compile-time interface checks
 var _ RemoteStorage = &Local{}
 var _ BatchDeleter = &Local{}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package storage
+
+import "os"
+
+// fsyncDir fsyncs a directory's inode so that a prior rename into it is
+// durable across a crash. Windows doesn't support opening/syncing directory
+// handles this way, so that platform gets a no-op (see local_fsync_windows.go).
+func fsyncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = d.Close()
+	}()
+	return d.Sync()
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package storage
+
+// fsyncDir is a no-op on Windows: NTFS/ReFS don't expose a directory fsync
+// equivalent, and the rename itself is already logged by the journal.
+func fsyncDir(dir string) error {
+	return nil
+}
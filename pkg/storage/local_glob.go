@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// globPrefix returns the longest path segment prefix of pattern that contains
+// no glob metacharacters, so WalkGlob/DeleteKeysGlob can start from a
+// subtree instead of walking the whole storage root and filtering in Go.
+func globPrefix(pattern string) string {
+	segments := strings.Split(filepath.ToSlash(pattern), "/")
+	var literal []string
+	for _, segment := range segments {
+		if strings.ContainsAny(segment, "*?[{") {
+			break
+		}
+		literal = append(literal, segment)
+	}
+	return strings.Join(literal, "/")
+}
+
+// WalkGlob walks remotePath matching relative paths against pattern (doublestar
+// syntax: *, **, ?, [...]). The walk starts at the pattern's leading literal
+// segments (see globPrefix); below that point every subdirectory is visited
+// and only individual files are filtered, no further subtrees are pruned.
+// With recursive=false the walk enters the immediate subdirectories of
+// remotePath but no deeper ones, so files up to two levels below remotePath
+// are matched; only files are passed to process, never directories.
+func (l *Local) WalkGlob(ctx context.Context, remotePath, pattern string, recursive bool, process func(context.Context, RemoteFile) error) error {
+	base, err := containedPath(l.Config.Path, remotePath)
+	if err != nil {
+		return err
+	}
+	prefix, err := containedPath(base, globPrefix(pattern))
+	if err != nil {
+		return err
+	}
+	l.Debug("[LOCAL_DEBUG] WalkGlob %s pattern=%s, recursive=%v", base, pattern, recursive)
+
+	return filepath.WalkDir(prefix, func(fPath string, d os.DirEntry, err error) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		relName, relErr := filepath.Rel(base, fPath)
+		if relErr != nil {
+			return relErr
+		}
+		if relName == "." {
+			return nil
+		}
+		if isInternalArtifact(d.Name()) {
+			return nil
+		}
+		relSlash := filepath.ToSlash(relName)
+		if d.IsDir() {
+			if !recursive && strings.Contains(relSlash, "/") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		matched, matchErr := doublestar.Match(pattern, relSlash)
+		if matchErr != nil {
+			return fmt.Errorf("invalid glob pattern %q: %w", pattern, matchErr)
+		}
+		if !matched {
+			return nil
+		}
+		info, infoErr := d.Info()
+		if infoErr != nil {
+			return infoErr
+		}
+		return process(ctx, &localFile{
+			size:         info.Size(),
+			lastModified: info.ModTime(),
+			name:         relName,
+			absPath:      fPath,
+		})
+	})
+}
+
+// DeleteKeysGlob deletes every key under Path matching any of patterns
+// (doublestar syntax), aggregating per-key failures into a BatchDeleteError
+// with the same shape as DeleteKeysBatch.
+func (l *Local) DeleteKeysGlob(ctx context.Context, patterns []string) error {
+	var failures []KeyError
+	deletedCount := 0
+
+	for _, pattern := range patterns {
+		walkErr := l.WalkGlob(ctx, "", pattern, true, func(ctx context.Context, file RemoteFile) error {
+			lf, ok := file.(*localFile)
+			if !ok {
+				return nil
+			}
+			if err := os.RemoveAll(lf.absPath); err != nil {
+				failures = append(failures, KeyError{Key: lf.name, Err: err})
+				return nil
+			}
+			deletedCount++
+			return nil
+		})
+		if walkErr != nil {
+			failures = append(failures, KeyError{Key: pattern, Err: walkErr})
+		}
+	}
+
+	if len(failures) > 0 {
+		return &BatchDeleteError{
+			Message:  fmt.Sprintf("LOCAL glob delete: %d keys deleted, %d failed", deletedCount, len(failures)),
+			Failures: failures,
+		}
+	}
+	l.Debug("[LOCAL_DEBUG] DeleteKeysGlob: successfully deleted %d keys across %d pattern(s)", deletedCount, len(patterns))
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build darwin
+
+package storage
+
+import "golang.org/x/sys/unix"
+
+// tryReflink attempts a copy-on-write clone of srcPath onto dstPath using
+// clonefile(2). clonefile requires dstPath to not exist yet, matching the
+// contract shared with the Linux/Windows implementations. It returns
+// ok=true when the clone succeeded, ok=false when the filesystem doesn't
+// support clones (caller should fall back to hardlink/copy), and a non-nil
+// err only for unexpected failures.
+func tryReflink(srcPath, dstPath string) (ok bool, err error) {
+	if err := unix.Clonefile(srcPath, dstPath, 0); err != nil {
+		switch err {
+		case unix.ENOTSUP, unix.EXDEV, unix.EINVAL, unix.EEXIST:
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,51 @@
+//go:build linux
+
+package storage
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// tryReflink attempts a copy-on-write clone of srcPath onto dstPath using the
+// FICLONE ioctl. dstPath must not already exist; tryReflink creates it itself
+// (removing it again on failure) so every platform's tryReflink shares the
+// same "dstPath must not exist beforehand" contract. It returns ok=true when
+// the clone succeeded, ok=false when the filesystem/kernel doesn't support
+// reflinks for this pair (caller should fall back to hardlink/copy), and a
+// non-nil err only for unexpected failures.
+func tryReflink(srcPath, dstPath string) (ok bool, err error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		// dstPath already existing (e.g. a retried object-disk copy) isn't a
+		// hard failure, it just means this pair can't be cloned: fall through
+		// to hardlink/copy like the other "unsupported" cases below.
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		_ = dst.Close()
+	}()
+
+	if err := unix.IoctlFileClone(int(dst.Fd()), int(src.Fd())); err != nil {
+		_ = os.Remove(dstPath)
+		switch err {
+		case unix.ENOTSUP, unix.EXDEV, unix.EINVAL:
+			return false, nil
+		default:
+			return false, err
+		}
+	}
+	return true, nil
+}
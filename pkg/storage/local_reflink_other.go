@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package storage
+
+// tryReflink is a no-op on platforms without a known reflink/CoW clone
+// syscall; CopyObject falls back to hardlink/copy.
+func tryReflink(srcPath, dstPath string) (ok bool, err error) {
+	return false, nil
+}
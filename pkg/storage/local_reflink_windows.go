@@ -0,0 +1,82 @@
+//go:build windows
+
+package storage
+
+import (
+	"errors"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// fsctlDuplicateExtentsToFile is FSCTL_DUPLICATE_EXTENTS_TO_FILE, supported by
+// ReFS (and dev-drive/Storage Spaces Direct volumes) to clone extents between
+// files without copying their data.
+const fsctlDuplicateExtentsToFile = 0x00098344
+
+// duplicateExtentsData mirrors DUPLICATE_EXTENTS_DATA from winioctl.h.
+type duplicateExtentsData struct {
+	FileHandle       windows.Handle
+	SourceFileOffset int64
+	TargetFileOffset int64
+	ByteCount        int64
+}
+
+// tryReflink attempts a block-clone of srcPath onto dstPath via
+// FSCTL_DUPLICATE_EXTENTS_TO_FILE. dstPath must not already exist;
+// tryReflink creates it itself (removing it again on failure), matching the
+// contract shared with the Linux/macOS implementations. It returns ok=true
+// when the clone succeeded, ok=false when the volume isn't ReFS or doesn't
+// support extent cloning (caller should fall back to hardlink/copy), and a
+// non-nil err only for unexpected failures.
+func tryReflink(srcPath, dstPath string) (ok bool, err error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = src.Close()
+	}()
+	info, statErr := src.Stat()
+	if statErr != nil {
+		return false, statErr
+	}
+	dst, err := os.OpenFile(dstPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		// dstPath already existing (e.g. a retried object-disk copy) isn't a
+		// hard failure, it just means this pair can't be cloned: fall through
+		// to hardlink/copy like the other "unsupported" cases below.
+		if errors.Is(err, os.ErrExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer func() {
+		_ = dst.Close()
+	}()
+
+	in := duplicateExtentsData{
+		FileHandle: windows.Handle(src.Fd()),
+		ByteCount:  info.Size(),
+	}
+	var bytesReturned uint32
+	ctlErr := windows.DeviceIoControl(
+		windows.Handle(dst.Fd()),
+		fsctlDuplicateExtentsToFile,
+		(*byte)(unsafe.Pointer(&in)),
+		uint32(unsafe.Sizeof(in)),
+		nil, 0,
+		&bytesReturned, nil,
+	)
+	if ctlErr != nil {
+		_ = os.Remove(dstPath)
+		switch ctlErr {
+		case windows.ERROR_INVALID_FUNCTION, windows.ERROR_NOT_SUPPORTED:
+			return false, nil
+		default:
+			return false, ctlErr
+		}
+	}
+	return true, nil
+}
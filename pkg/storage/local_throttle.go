@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// ioAccounting tracks cumulative bytes moved through a Local instance's
+// upload/download paths, analogous to rclone's fs/accounting, so a future
+// --progress flag can report per-storage throughput. Bytes are counted only
+// by throttledWriter/throttledReader, which Local installs only when
+// MaxUploadBytesPerSec/MaxDownloadBytesPerSec is set; transfers with no
+// byte-rate limit configured are not counted.
+type ioAccounting struct {
+	mu         sync.Mutex
+	uploaded   int64
+	downloaded int64
+}
+
+func (a *ioAccounting) addUploaded(n int64) {
+	a.mu.Lock()
+	a.uploaded += n
+	a.mu.Unlock()
+}
+
+func (a *ioAccounting) addDownloaded(n int64) {
+	a.mu.Lock()
+	a.downloaded += n
+	a.mu.Unlock()
+}
+
+func (a *ioAccounting) snapshot() (uploaded, downloaded int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.uploaded, a.downloaded
+}
+
+// throttledWriter wraps an io.Writer, waiting on a shared token-bucket
+// limiter before each write and recording bytes moved into accounting. A nil
+// limiter (the default, unlimited) just passes writes through.
+type throttledWriter struct {
+	w          io.Writer
+	limiter    *rate.Limiter
+	ctx        context.Context
+	accounting *ioAccounting
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	if t.limiter != nil {
+		if err := waitForTokens(t.ctx, t.limiter, len(p)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := t.w.Write(p)
+	if t.accounting != nil {
+		t.accounting.addUploaded(int64(n))
+	}
+	return n, err
+}
+
+// throttledReader mirrors throttledWriter for the download/read path.
+type throttledReader struct {
+	r          io.Reader
+	limiter    *rate.Limiter
+	ctx        context.Context
+	accounting *ioAccounting
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if t.limiter != nil {
+		if err := waitForTokens(t.ctx, t.limiter, len(p)); err != nil {
+			return 0, err
+		}
+	}
+	n, err := t.r.Read(p)
+	if t.accounting != nil {
+		t.accounting.addDownloaded(int64(n))
+	}
+	return n, err
+}
+
+// throttledReadCloser adapts a throttledReader to io.ReadCloser by delegating
+// Close to the wrapped reader.
+type throttledReadCloser struct {
+	rc io.ReadCloser
+	r  throttledReader
+}
+
+func (t *throttledReadCloser) Read(p []byte) (int, error) {
+	return t.r.Read(p)
+}
+
+func (t *throttledReadCloser) Close() error {
+	return t.rc.Close()
+}
+
+// waitForTokens waits for n tokens from limiter, in chunks no larger than its
+// burst size, and
+// honors ctx.Done() while waiting so a cancelled backup doesn't hang throttled.
+func waitForTokens(ctx context.Context, limiter *rate.Limiter, n int) error {
+	burst := limiter.Burst()
+	for n > 0 {
+		chunk := n
+		if burst > 0 && chunk > burst {
+			chunk = burst
+		}
+		if err := limiter.WaitN(ctx, chunk); err != nil {
+			return err
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// rateLimiter builds a *rate.Limiter from a per-second config value (bytes
+// for the upload/download limits, operations for MaxIOPS), or
+// nil when unset/unlimited.
+func rateLimiter(bytesPerSec int) *rate.Limiter {
+	if bytesPerSec <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(bytesPerSec), bytesPerSec)
+}